@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"context"
+	"io/fs"
+
+	"github.com/aquasecurity/trivy/pkg/fanal/types"
+)
+
+// Type identifies a post-analyzer, e.g. the one that parses poetry.lock
+// files.
+type Type string
+
+const (
+	TypePoetry Type = "poetry"
+)
+
+// AnalyzerOptions carries options that affect how a PostAnalyzer behaves.
+type AnalyzerOptions struct {
+	// Groups restricts post-analyzers that classify packages into
+	// dependency groups (e.g. npm, Poetry) to the named groups. A nil/empty
+	// slice keeps every group.
+	Groups []string
+}
+
+// PostAnalysisInput is the input passed to a PostAnalyzer.
+type PostAnalysisInput struct {
+	// FS is rooted at the directory being scanned.
+	FS fs.FS
+}
+
+// AnalysisResult is what a PostAnalyzer returns.
+type AnalysisResult struct {
+	Applications []types.Application
+}
+
+// PostAnalyzer runs after the initial filesystem walk, examining files that
+// belong to a specific language ecosystem (e.g. a lock file) in more detail
+// than the walk alone can.
+type PostAnalyzer interface {
+	PostAnalyze(ctx context.Context, input PostAnalysisInput) (*AnalysisResult, error)
+	// Required reports whether filePath should be handed to PostAnalyze.
+	Required(filePath string, info fs.FileInfo) bool
+	Type() Type
+	Version() int
+}
+
+// NewPostAnalyzer constructs a PostAnalyzer from the options the scanner
+// was configured with.
+type NewPostAnalyzer func(options AnalyzerOptions) (PostAnalyzer, error)
+
+var postAnalyzers = map[Type]NewPostAnalyzer{}
+
+// RegisterPostAnalyzer registers a PostAnalyzer constructor under t, so the
+// scanner can instantiate it once AnalyzerOptions are known.
+func RegisterPostAnalyzer(t Type, newAnalyzer NewPostAnalyzer) {
+	postAnalyzers[t] = newAnalyzer
+}
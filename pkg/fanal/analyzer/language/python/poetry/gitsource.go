@@ -0,0 +1,111 @@
+package poetry
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/aquasecurity/trivy/pkg/fanal/types"
+)
+
+// scpLikeURL matches SSH/SCP-style git URLs such as
+// `git@github.com:tiangolo/fastapi.git`.
+var scpLikeURL = regexp.MustCompile(`^[^@/]+@([^:/]+):(.+?)(?:\.git)?/?$`)
+
+// gitSourceRef resolves the upstream repository and version-like ref (tag,
+// rev, branch or resolved_reference, in that preference order) for a
+// git-sourced package, if any. ok is false when the package isn't
+// git-sourced or its URL isn't a GitHub URL we recognize.
+func gitSourceRef(p lockPackage, proj *pyProjectInfo) (ref, repo string, ok bool) {
+	var gitURL, rev, branch, resolvedRef, tag string
+
+	if p.isGitSource() {
+		gitURL = p.Source.URL
+		rev = p.Source.Reference
+		resolvedRef = p.Source.ResolvedReference
+	}
+
+	if proj != nil {
+		if dep, ok := proj.GitDependencies[normalizeName(p.Name)]; ok {
+			if gitURL == "" {
+				gitURL = dep.URL
+			}
+			tag = dep.Tag
+			if rev == "" {
+				rev = dep.Rev
+			}
+			if branch == "" {
+				branch = dep.Branch
+			}
+		}
+	}
+
+	if gitURL == "" {
+		return "", "", false
+	}
+
+	repo, ok = normalizeGitURL(gitURL)
+	if !ok {
+		return "", "", false
+	}
+
+	ref = firstNonEmpty(tag, rev, branch, resolvedRef)
+	return ref, repo, true
+}
+
+// applyGitSource rewrites a package resolved from a git repository so that
+// it records the upstream repository and a version-like ref instead of the
+// `0.0.0` placeholder Poetry writes for VCS dependencies. Without this, the
+// package can never be matched against vulnerability advisories.
+func applyGitSource(pkg *types.Package, p lockPackage, proj *pyProjectInfo) {
+	ref, repo, ok := gitSourceRef(p, proj)
+	if !ok {
+		return
+	}
+
+	if ref != "" {
+		pkg.Version = ref
+		pkg.ID = packageID(pkg.Name, ref)
+	}
+
+	pkg.ExternalReferences = append(pkg.ExternalReferences, types.ExternalRef{
+		Type: types.RefVCS,
+		URL:  "https://github.com/" + repo,
+	})
+}
+
+// normalizeGitURL turns an HTTPS or SCP-style GitHub URL into an "owner/repo"
+// string. It returns false for URLs it doesn't recognize as GitHub.
+func normalizeGitURL(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+
+	switch {
+	case strings.HasPrefix(raw, "https://github.com/"), strings.HasPrefix(raw, "http://github.com/"):
+		path := raw[strings.Index(raw, "github.com/")+len("github.com/"):]
+		return trimGitSuffix(path), path != ""
+	case strings.HasPrefix(raw, "git@github.com:"):
+		m := scpLikeURL.FindStringSubmatch(raw)
+		if m == nil {
+			return "", false
+		}
+		return trimGitSuffix(m[2]), true
+	case strings.HasPrefix(raw, "ssh://git@github.com/"):
+		path := raw[strings.Index(raw, "github.com/")+len("github.com/"):]
+		return trimGitSuffix(path), path != ""
+	default:
+		return "", false
+	}
+}
+
+func trimGitSuffix(path string) string {
+	path = strings.TrimSuffix(path, "/")
+	return strings.TrimSuffix(path, ".git")
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
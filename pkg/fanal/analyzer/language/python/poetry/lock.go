@@ -0,0 +1,45 @@
+package poetry
+
+import (
+	"io"
+
+	"github.com/BurntSushi/toml"
+	"golang.org/x/xerrors"
+)
+
+// lockSource describes the `[package.source]` table that Poetry writes for
+// packages that aren't resolved from a package index, e.g. git, url or path
+// dependencies.
+type lockSource struct {
+	Type              string `toml:"type"`
+	URL               string `toml:"url"`
+	Reference         string `toml:"reference"`
+	ResolvedReference string `toml:"resolved_reference"`
+}
+
+type lockPackage struct {
+	Name         string         `toml:"name"`
+	Version      string         `toml:"version"`
+	Optional     bool           `toml:"optional"`
+	Dependencies map[string]any `toml:"dependencies"`
+	Source       *lockSource    `toml:"source"`
+}
+
+type lockFile struct {
+	Package []lockPackage `toml:"package"`
+}
+
+// parseLock decodes a poetry.lock file into its package entries.
+func parseLock(r io.Reader) ([]lockPackage, error) {
+	var lf lockFile
+	if _, err := toml.NewDecoder(r).Decode(&lf); err != nil {
+		return nil, xerrors.Errorf("toml decode error: %w", err)
+	}
+	return lf.Package, nil
+}
+
+// isGitSource reports whether the package was resolved from a git repository
+// rather than a package index.
+func (p lockPackage) isGitSource() bool {
+	return p.Source != nil && p.Source.Type == "git"
+}
@@ -0,0 +1,272 @@
+package poetry
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/samber/lo"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/fanal/analyzer"
+	"github.com/aquasecurity/trivy/pkg/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+func init() {
+	analyzer.RegisterPostAnalyzer(analyzer.TypePoetry, newPoetryAnalyzer)
+}
+
+const (
+	version = 1
+
+	poetryLockFile = "poetry.lock"
+	pyProjectFile  = "pyproject.toml"
+)
+
+// poetryAnalyzer analyzes poetry.lock, cross-referencing it against the
+// sibling pyproject.toml (when present) to distinguish direct dependencies
+// from transitive ones and to tag each package with the dependency group(s)
+// it belongs to.
+type poetryAnalyzer struct {
+	// groups restricts reporting to the named dependency groups (e.g.
+	// "main", "dev", "lint"). A nil/empty slice means every group is kept.
+	groups []string
+}
+
+func newPoetryAnalyzer(opts analyzer.AnalyzerOptions) (analyzer.PostAnalyzer, error) {
+	return poetryAnalyzer{groups: opts.Groups}, nil
+}
+
+func (a poetryAnalyzer) PostAnalyze(_ context.Context, input analyzer.PostAnalysisInput) (*analyzer.AnalysisResult, error) {
+	var apps []types.Application
+
+	err := fs.WalkDir(input.FS, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !a.Required(path, nil) {
+			return nil
+		}
+
+		app, err := a.parsePoetryLock(input.FS, path)
+		if err != nil {
+			log.Logger.Warnf("Unable to parse %q: %s", path, err)
+			return nil
+		}
+		if app == nil {
+			return nil
+		}
+		apps = append(apps, *app)
+		return nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("walk error: %w", err)
+	}
+
+	// Cross-reference against an installed site-packages tree, if any, so
+	// that lock-resolved packages carry the files they actually own and any
+	// distribution installed outside of Poetry's control is still reported.
+	installed, err := findInstalledPackages(input.FS)
+	if err != nil {
+		log.Logger.Debugf("Unable to walk for installed distributions: %s", err)
+	} else if len(installed) > 0 {
+		apps = append(apps, reconcileInstalledPackages(apps, installed)...)
+	}
+
+	return &analyzer.AnalysisResult{Applications: apps}, nil
+}
+
+func (a poetryAnalyzer) parsePoetryLock(fsys fs.FS, lockPath string) (*types.Application, error) {
+	f, err := fsys.Open(lockPath)
+	if err != nil {
+		return nil, xerrors.Errorf("file open error: %w", err)
+	}
+	defer f.Close()
+
+	lockPkgs, err := parseLock(f)
+	if err != nil {
+		// A broken lock file shouldn't fail the whole scan.
+		log.Logger.Debugf("Unable to parse %q as a poetry.lock: %s", lockPath, err)
+		return nil, nil
+	}
+
+	dir := filepath.Dir(lockPath)
+	proj, err := readPyProject(fsys, filepath.Join(dir, pyProjectFile))
+	if err != nil {
+		// Missing or malformed pyproject.toml: fall back to reporting every
+		// locked package without relationship information.
+		log.Logger.Debugf("Unable to parse pyproject.toml next to %q: %s", lockPath, err)
+		proj = nil
+	}
+
+	pkgs := buildPackages(lockPkgs, proj, a.groups)
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+
+	sort.Sort(pkgs)
+
+	return &types.Application{
+		Type:     types.Poetry,
+		FilePath: lockPath,
+		Packages: pkgs,
+	}, nil
+}
+
+// buildPackages turns the parsed lock entries into types.Package, tagging
+// each with the dependency group(s) it belongs to and marking direct vs.
+// indirect relationships when a pyproject.toml is available. allowedGroups
+// restricts the result to those groups; a nil/empty value keeps all of them.
+// Without a pyproject.toml, allowedGroups is ignored and every locked package
+// is kept.
+func buildPackages(lockPkgs []lockPackage, proj *pyProjectInfo, allowedGroups []string) types.Packages {
+	byName := make(map[string]lockPackage, len(lockPkgs))
+	for _, p := range lockPkgs {
+		byName[normalizeName(p.Name)] = p
+	}
+
+	// Git-sourced packages get their ID/version rewritten from the lock
+	// file's placeholder `0.0.0` to an upstream ref (see applyGitSource).
+	// Every other package's DependsOn must point at that rewritten ID, so
+	// resolve it for every lock entry up front rather than depending on
+	// applyGitSource having already run for a given dependency.
+	resolvedID := make(map[string]string, len(lockPkgs))
+	for _, p := range lockPkgs {
+		id := packageID(p.Name, p.Version)
+		if ref, _, ok := gitSourceRef(p, proj); ok && ref != "" {
+			id = packageID(p.Name, ref)
+		}
+		resolvedID[normalizeName(p.Name)] = id
+	}
+
+	// Without a pyproject.toml, we have no way to tell direct dependencies
+	// apart from transitive ones, or which group they belong to, so every
+	// locked package is reported as-is.
+	include := func(string) bool { return true }
+	var reachableByGroup map[string]map[string]struct{}
+	var directNames map[string]struct{}
+
+	if proj != nil {
+		allowed := allowedGroups
+		if len(allowed) == 0 {
+			for group := range proj.GroupDependencies {
+				allowed = append(allowed, group)
+			}
+		}
+
+		reachableByGroup = make(map[string]map[string]struct{}, len(allowed))
+		directNames = make(map[string]struct{})
+		combined := make(map[string]struct{})
+		for _, group := range allowed {
+			roots := proj.GroupDependencies[group]
+			for name := range roots {
+				directNames[name] = struct{}{}
+			}
+
+			reachable := reachableFrom(roots, byName)
+			reachableByGroup[group] = reachable
+			for name := range reachable {
+				combined[name] = struct{}{}
+			}
+		}
+
+		include = func(name string) bool {
+			_, ok := combined[name]
+			return ok
+		}
+	}
+
+	var pkgs types.Packages
+	for _, p := range lockPkgs {
+		name := normalizeName(p.Name)
+		if !include(name) {
+			continue
+		}
+
+		pkg := types.Package{
+			ID:      packageID(p.Name, p.Version),
+			Name:    p.Name,
+			Version: p.Version,
+		}
+
+		if proj != nil {
+			var groups []string
+			for group, reachable := range reachableByGroup {
+				if _, ok := reachable[name]; ok {
+					groups = append(groups, group)
+				}
+			}
+			sort.Strings(groups)
+			pkg.Groups = groups
+			pkg.Dev = !lo.Contains(groups, mainGroupName)
+
+			if _, ok := proj.Optional[name]; ok {
+				pkg.Optional = true
+			}
+
+			if _, ok := directNames[name]; ok {
+				pkg.Relationship = types.RelationshipDirect
+			} else {
+				pkg.Indirect = true
+				pkg.Relationship = types.RelationshipIndirect
+			}
+		}
+
+		for dep := range p.Dependencies {
+			depName := normalizeName(dep)
+			if _, ok := byName[depName]; !ok || !include(depName) {
+				continue
+			}
+			pkg.DependsOn = append(pkg.DependsOn, resolvedID[depName])
+		}
+		sort.Strings(pkg.DependsOn)
+
+		applyGitSource(&pkg, p, proj)
+
+		pkgs = append(pkgs, pkg)
+	}
+
+	return pkgs
+}
+
+// reachableFrom walks the dependency graph starting at roots and returns the
+// set of (normalized) package names reachable from it, roots included.
+func reachableFrom(roots map[string]struct{}, byName map[string]lockPackage) map[string]struct{} {
+	seen := make(map[string]struct{})
+	var visit func(name string)
+	visit = func(name string) {
+		if _, ok := seen[name]; ok {
+			return
+		}
+		p, ok := byName[name]
+		if !ok {
+			return
+		}
+		seen[name] = struct{}{}
+		for dep := range p.Dependencies {
+			visit(normalizeName(dep))
+		}
+	}
+	for name := range roots {
+		visit(name)
+	}
+	return seen
+}
+
+func packageID(name, version string) string {
+	return name + "@" + version
+}
+
+func (a poetryAnalyzer) Required(filePath string, _ fs.FileInfo) bool {
+	return filepath.Base(filePath) == poetryLockFile
+}
+
+func (a poetryAnalyzer) Type() analyzer.Type {
+	return analyzer.TypePoetry
+}
+
+func (a poetryAnalyzer) Version() int {
+	return version
+}
@@ -14,9 +14,10 @@ import (
 
 func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 	tests := []struct {
-		name string
-		dir  string
-		want *analyzer.AnalysisResult
+		name   string
+		dir    string
+		groups []string
+		want   *analyzer.AnalysisResult
 	}{
 		{
 			name: "happy path",
@@ -33,6 +34,7 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 								Version:      "2022.12.7",
 								Indirect:     true,
 								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
 							},
 							{
 								ID:           "charset-normalizer@2.1.1",
@@ -40,6 +42,7 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 								Version:      "2.1.1",
 								Indirect:     true,
 								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
 							},
 							{
 								ID:           "click@7.1.2",
@@ -47,12 +50,14 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 								Version:      "7.1.2",
 								Indirect:     true,
 								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
 							},
 							{
 								ID:           "flask@1.1.4",
 								Name:         "flask",
 								Version:      "1.1.4",
 								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
 								DependsOn: []string{
 									"click@7.1.2",
 									"itsdangerous@1.1.0",
@@ -66,6 +71,7 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 								Version:      "3.4",
 								Indirect:     true,
 								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
 							},
 							{
 								ID:           "itsdangerous@1.1.0",
@@ -73,6 +79,7 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 								Version:      "1.1.0",
 								Indirect:     true,
 								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
 							},
 							{
 								ID:           "jinja2@2.11.3",
@@ -80,6 +87,7 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 								Version:      "2.11.3",
 								Indirect:     true,
 								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
 								DependsOn: []string{
 									"markupsafe@2.1.2",
 								},
@@ -90,12 +98,14 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 								Version:      "2.1.2",
 								Indirect:     true,
 								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
 							},
 							{
 								ID:           "requests@2.28.1",
 								Name:         "requests",
 								Version:      "2.28.1",
 								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
 								DependsOn: []string{
 									"certifi@2022.12.7",
 									"charset-normalizer@2.1.1",
@@ -109,6 +119,7 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 								Version:      "1.26.14",
 								Indirect:     true,
 								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
 							},
 							{
 								ID:           "werkzeug@1.0.1",
@@ -116,6 +127,7 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 								Version:      "1.0.1",
 								Indirect:     true,
 								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
 							},
 						},
 					},
@@ -190,7 +202,7 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 			// poetry add --group dev pytest@8.3.4
 			// poetry add --group lint ruff@0.8.3
 			// poetry add --optional typing-inspect@0.9.0
-			name: "skip deps from groups",
+			name: "preserve dependency-group metadata",
 			dir:  "testdata/with-groups",
 			want: &analyzer.AnalysisResult{
 				Applications: []types.Application{
@@ -204,6 +216,7 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 								Version:      "2024.12.14",
 								Indirect:     true,
 								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
 							},
 							{
 								ID:           "charset-normalizer@3.4.0",
@@ -211,6 +224,25 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 								Version:      "3.4.0",
 								Indirect:     true,
 								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
+							},
+							{
+								ID:           "colorama@0.4.6",
+								Name:         "colorama",
+								Version:      "0.4.6",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"dev"},
+								Dev:          true,
+							},
+							{
+								ID:           "exceptiongroup@1.2.2",
+								Name:         "exceptiongroup",
+								Version:      "1.2.2",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"dev"},
+								Dev:          true,
 							},
 							{
 								ID:           "idna@3.10",
@@ -218,6 +250,16 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 								Version:      "3.10",
 								Indirect:     true,
 								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
+							},
+							{
+								ID:           "iniconfig@2.0.0",
+								Name:         "iniconfig",
+								Version:      "2.0.0",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"dev"},
+								Dev:          true,
 							},
 							{
 								ID:           "mypy-extensions@1.0.0",
@@ -225,6 +267,41 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 								Version:      "1.0.0",
 								Indirect:     true,
 								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
+							},
+							{
+								ID:           "packaging@24.2",
+								Name:         "packaging",
+								Version:      "24.2",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"dev"},
+								Dev:          true,
+							},
+							{
+								ID:           "pluggy@1.5.0",
+								Name:         "pluggy",
+								Version:      "1.5.0",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"dev"},
+								Dev:          true,
+							},
+							{
+								ID:      "pytest@8.3.4",
+								Name:    "pytest",
+								Version: "8.3.4",
+								DependsOn: []string{
+									"colorama@0.4.6",
+									"exceptiongroup@1.2.2",
+									"iniconfig@2.0.0",
+									"packaging@24.2",
+									"pluggy@1.5.0",
+									"tomli@2.2.1",
+								},
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"dev"},
+								Dev:          true,
 							},
 							{
 								ID:      "requests@2.32.3",
@@ -237,13 +314,24 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 									"urllib3@2.2.3",
 								},
 								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
 							},
 							{
 								ID:           "ruff@0.8.3",
 								Name:         "ruff",
 								Version:      "0.8.3",
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"lint"},
+								Dev:          true,
+							},
+							{
+								ID:           "tomli@2.2.1",
+								Name:         "tomli",
+								Version:      "2.2.1",
 								Indirect:     true,
 								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"dev"},
+								Dev:          true,
 							},
 							{
 								ID:           "typing-extensions@4.12.2",
@@ -251,6 +339,7 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 								Version:      "4.12.2",
 								Indirect:     true,
 								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
 							},
 							{
 								ID:      "typing-inspect@0.9.0",
@@ -261,6 +350,68 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 									"typing-extensions@4.12.2",
 								},
 								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
+								Optional:     true,
+							},
+							{
+								ID:           "urllib3@2.2.3",
+								Name:         "urllib3",
+								Version:      "2.2.3",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:   "restrict dependency groups via AnalyzerOptions.Groups",
+			dir:    "testdata/with-groups",
+			groups: []string{"main"},
+			want: &analyzer.AnalysisResult{
+				Applications: []types.Application{
+					{
+						Type:     types.Poetry,
+						FilePath: "poetry.lock",
+						Packages: types.Packages{
+							{
+								ID:           "certifi@2024.12.14",
+								Name:         "certifi",
+								Version:      "2024.12.14",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
+							},
+							{
+								ID:           "charset-normalizer@3.4.0",
+								Name:         "charset-normalizer",
+								Version:      "3.4.0",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
+							},
+							{
+								ID:           "idna@3.10",
+								Name:         "idna",
+								Version:      "3.10",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
+							},
+							{
+								ID:      "requests@2.32.3",
+								Name:    "requests",
+								Version: "2.32.3",
+								DependsOn: []string{
+									"certifi@2024.12.14",
+									"charset-normalizer@3.4.0",
+									"idna@3.10",
+									"urllib3@2.2.3",
+								},
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
 							},
 							{
 								ID:           "urllib3@2.2.3",
@@ -268,6 +419,402 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 								Version:      "2.2.3",
 								Indirect:     true,
 								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "git dependency, https url with tag",
+			dir:  "testdata/git-deps/https-tag",
+			want: &analyzer.AnalysisResult{
+				Applications: []types.Application{
+					{
+						Type:     types.Poetry,
+						FilePath: "poetry.lock",
+						Packages: types.Packages{
+							{
+								ID:           "fastapi@1.2.3",
+								Name:         "fastapi",
+								Version:      "1.2.3",
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
+								ExternalReferences: []types.ExternalRef{
+									{
+										Type: types.RefVCS,
+										URL:  "https://github.com/tiangolo/fastapi",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "git dependency, ssh url with tag",
+			dir:  "testdata/git-deps/ssh-tag",
+			want: &analyzer.AnalysisResult{
+				Applications: []types.Application{
+					{
+						Type:     types.Poetry,
+						FilePath: "poetry.lock",
+						Packages: types.Packages{
+							{
+								ID:           "fastapi@1.2.3",
+								Name:         "fastapi",
+								Version:      "1.2.3",
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
+								ExternalReferences: []types.ExternalRef{
+									{
+										Type: types.RefVCS,
+										URL:  "https://github.com/tiangolo/fastapi",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "git dependency, rev only",
+			dir:  "testdata/git-deps/rev-only",
+			want: &analyzer.AnalysisResult{
+				Applications: []types.Application{
+					{
+						Type:     types.Poetry,
+						FilePath: "poetry.lock",
+						Packages: types.Packages{
+							{
+								ID:           "fastapi@deadbeef",
+								Name:         "fastapi",
+								Version:      "deadbeef",
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
+								ExternalReferences: []types.ExternalRef{
+									{
+										Type: types.RefVCS,
+										URL:  "https://github.com/tiangolo/fastapi",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "git dependency referenced from another package's DependsOn",
+			dir:  "testdata/git-deps/dependent",
+			want: &analyzer.AnalysisResult{
+				Applications: []types.Application{
+					{
+						Type:     types.Poetry,
+						FilePath: "poetry.lock",
+						Packages: types.Packages{
+							{
+								ID:           "fastapi@1.2.3",
+								Name:         "fastapi",
+								Version:      "1.2.3",
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
+								ExternalReferences: []types.ExternalRef{
+									{
+										Type: types.RefVCS,
+										URL:  "https://github.com/tiangolo/fastapi",
+									},
+								},
+							},
+							{
+								ID:      "httpx-fastapi-demo@1.0.0",
+								Name:    "httpx-fastapi-demo",
+								Version: "1.0.0",
+								DependsOn: []string{
+									"fastapi@1.2.3",
+								},
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "PEP 621 [project] table only",
+			dir:  "testdata/pep621/project-only",
+			want: &analyzer.AnalysisResult{
+				Applications: []types.Application{
+					{
+						Type:     types.Poetry,
+						FilePath: "poetry.lock",
+						Packages: types.Packages{
+							{
+								ID:           "click@8.1.3",
+								Name:         "click",
+								Version:      "8.1.3",
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
+							},
+							{
+								ID:           "mypy-extensions@1.0.0",
+								Name:         "mypy-extensions",
+								Version:      "1.0.0",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"typing"},
+								Dev:          true,
+							},
+							{
+								ID:           "pytest@8.3.4",
+								Name:         "pytest",
+								Version:      "8.3.4",
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"dev"},
+								Dev:          true,
+							},
+							{
+								ID:           "typing-extensions@4.12.2",
+								Name:         "typing-extensions",
+								Version:      "4.12.2",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"typing"},
+								Dev:          true,
+							},
+							{
+								ID:      "typing-inspect@0.9.0",
+								Name:    "typing-inspect",
+								Version: "0.9.0",
+								DependsOn: []string{
+									"mypy-extensions@1.0.0",
+									"typing-extensions@4.12.2",
+								},
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"typing"},
+								Dev:          true,
+								Optional:     true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "legacy [tool.poetry] table only",
+			dir:  "testdata/pep621/tool-poetry-only",
+			want: &analyzer.AnalysisResult{
+				Applications: []types.Application{
+					{
+						Type:     types.Poetry,
+						FilePath: "poetry.lock",
+						Packages: types.Packages{
+							{
+								ID:           "click@8.1.3",
+								Name:         "click",
+								Version:      "8.1.3",
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "hybrid [project] and [tool.poetry] tables",
+			dir:  "testdata/pep621/hybrid",
+			want: &analyzer.AnalysisResult{
+				Applications: []types.Application{
+					{
+						Type:     types.Poetry,
+						FilePath: "poetry.lock",
+						Packages: types.Packages{
+							{
+								ID:           "certifi@2024.12.14",
+								Name:         "certifi",
+								Version:      "2024.12.14",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
+							},
+							{
+								ID:           "charset-normalizer@3.4.0",
+								Name:         "charset-normalizer",
+								Version:      "3.4.0",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
+							},
+							{
+								ID:           "click@8.1.3",
+								Name:         "click",
+								Version:      "8.1.3",
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
+							},
+							{
+								ID:           "idna@3.10",
+								Name:         "idna",
+								Version:      "3.10",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
+							},
+							{
+								ID:      "requests@2.32.3",
+								Name:    "requests",
+								Version: "2.32.3",
+								DependsOn: []string{
+									"certifi@2024.12.14",
+									"charset-normalizer@3.4.0",
+									"idna@3.10",
+									"urllib3@2.2.3",
+								},
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
+							},
+							{
+								ID:           "urllib3@2.2.3",
+								Name:         "urllib3",
+								Version:      "2.2.3",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "reconcile against installed site-packages",
+			dir:  "testdata/site-packages",
+			want: &analyzer.AnalysisResult{
+				Applications: []types.Application{
+					{
+						Type:     types.Poetry,
+						FilePath: "poetry.lock",
+						Packages: types.Packages{
+							{
+								ID:           "certifi@2022.12.7",
+								Name:         "certifi",
+								Version:      "2022.12.7",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
+							},
+							{
+								ID:           "charset-normalizer@2.1.1",
+								Name:         "charset-normalizer",
+								Version:      "2.1.1",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
+							},
+							{
+								ID:           "click@8.1.3",
+								Name:         "click",
+								Version:      "8.1.3",
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
+								InstalledFiles: []string{
+									"site-packages/click/__init__.py",
+									"site-packages/click/core.py",
+									"site-packages/click-8.1.3.dist-info/INSTALLER",
+									"site-packages/click-8.1.3.dist-info/METADATA",
+									"site-packages/click-8.1.3.dist-info/RECORD",
+								},
+							},
+							{
+								ID:           "idna@3.4",
+								Name:         "idna",
+								Version:      "3.4",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
+							},
+							{
+								ID:      "requests@2.28.1",
+								Name:    "requests",
+								Version: "2.28.1",
+								DependsOn: []string{
+									"certifi@2022.12.7",
+									"charset-normalizer@2.1.1",
+									"idna@3.4",
+									"urllib3@1.26.14",
+								},
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
+							},
+							{
+								ID:           "urllib3@1.26.14",
+								Name:         "urllib3",
+								Version:      "1.26.14",
+								Indirect:     true,
+								Relationship: types.RelationshipIndirect,
+								Groups:       []string{"main"},
+							},
+						},
+					},
+					{
+						Type:     types.PythonPkg,
+						FilePath: "site-packages/setuptools-65.5.0.dist-info/RECORD",
+						Packages: types.Packages{
+							{
+								ID:      "setuptools@65.5.0",
+								Name:    "setuptools",
+								Version: "65.5.0",
+								InstalledFiles: []string{
+									"site-packages/setuptools/__init__.py",
+									"site-packages/setuptools/_vendor/__init__.py",
+									"site-packages/setuptools-65.5.0.dist-info/INSTALLER",
+									"site-packages/setuptools-65.5.0.dist-info/METADATA",
+									"site-packages/setuptools-65.5.0.dist-info/RECORD",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "installed site-packages version differs from the lock file",
+			dir:  "testdata/site-packages-version-mismatch",
+			want: &analyzer.AnalysisResult{
+				Applications: []types.Application{
+					{
+						Type:     types.Poetry,
+						FilePath: "poetry.lock",
+						Packages: types.Packages{
+							{
+								ID:           "click@8.1.3",
+								Name:         "click",
+								Version:      "8.1.3",
+								Relationship: types.RelationshipDirect,
+								Groups:       []string{"main"},
+							},
+						},
+					},
+					{
+						Type:     types.PythonPkg,
+						FilePath: "site-packages/click-8.2.0.dist-info/RECORD",
+						Packages: types.Packages{
+							{
+								ID:      "click@8.2.0",
+								Name:    "click",
+								Version: "8.2.0",
+								InstalledFiles: []string{
+									"site-packages/click/__init__.py",
+									"site-packages/click/core.py",
+									"site-packages/click-8.2.0.dist-info/INSTALLER",
+									"site-packages/click-8.2.0.dist-info/METADATA",
+									"site-packages/click-8.2.0.dist-info/RECORD",
+								},
 							},
 						},
 					},
@@ -278,7 +825,7 @@ func Test_poetryLibraryAnalyzer_Analyze(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			a, err := newPoetryAnalyzer(analyzer.AnalyzerOptions{})
+			a, err := newPoetryAnalyzer(analyzer.AnalyzerOptions{Groups: tt.groups})
 			require.NoError(t, err)
 
 			got, err := a.PostAnalyze(context.Background(), analyzer.PostAnalysisInput{
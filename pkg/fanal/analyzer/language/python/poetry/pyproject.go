@@ -0,0 +1,279 @@
+package poetry
+
+import (
+	"io"
+	"io/fs"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"golang.org/x/xerrors"
+)
+
+// mainGroupName is the implicit group for `[tool.poetry.dependencies]`,
+// Poetry's equivalent of npm's "dependencies" (as opposed to a named group
+// under `[tool.poetry.group.<name>]`, Poetry's equivalent of npm's
+// "devDependencies"/"peerDependencies").
+const mainGroupName = "main"
+
+// devGroupName is the conventional Poetry dependency group for
+// development-only tooling (tests, linters, type checkers, ...).
+const devGroupName = "dev"
+
+// pythonPseudoDependency is the key Poetry uses in `[tool.poetry.dependencies]`
+// to pin the interpreter version. It isn't a real package.
+const pythonPseudoDependency = "python"
+
+type rawPyProject struct {
+	// Project holds the PEP 621 `[project]` table that Poetry 2.0 uses for
+	// core metadata, in place of the legacy `[tool.poetry]` fields below.
+	Project struct {
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+
+	// DependencyGroups is the PEP 735 top-level `[dependency-groups]` table.
+	// Each entry is a list of PEP 508 requirement strings and/or
+	// `{include-group = "<name>"}` references to another group.
+	DependencyGroups map[string][]any `toml:"dependency-groups"`
+
+	Tool struct {
+		Poetry struct {
+			Dependencies map[string]any                `toml:"dependencies"`
+			Group        map[string]rawDependencyGroup `toml:"group"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+type rawDependencyGroup struct {
+	Optional     bool           `toml:"optional"`
+	Dependencies map[string]any `toml:"dependencies"`
+}
+
+// pep508Name extracts the distribution name from a PEP 508 requirement
+// string, e.g. "requests[security] (>=2.28.1,<3.0.0); python_version >= \"3.8\""
+// yields "requests". Extras, version specifiers and environment markers are
+// all ignored: only the direct/indirect relationship is derived from this,
+// never the constraint itself.
+var pep508NamePattern = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)`)
+
+func pep508Name(requirement string) (string, bool) {
+	m := pep508NamePattern.FindStringSubmatch(requirement)
+	if m == nil {
+		return "", false
+	}
+	return normalizeName(m[1]), true
+}
+
+// gitDependency is a git-sourced requirement declared directly in
+// pyproject.toml, e.g. `fastapi = {git = "https://github.com/tiangolo/fastapi.git", tag = "1.2.3"}`.
+type gitDependency struct {
+	URL    string
+	Tag    string
+	Rev    string
+	Branch string
+}
+
+// pyProjectInfo is the subset of pyproject.toml that the poetry analyzer
+// needs in order to classify lock entries.
+type pyProjectInfo struct {
+	// MainDependencies holds the normalized names declared directly under
+	// `[tool.poetry.dependencies]` or PEP 621 `[project].dependencies`, i.e.
+	// production dependencies.
+	MainDependencies map[string]struct{}
+	// GroupDependencies maps a group name (e.g. "dev", "lint") to the
+	// normalized names declared under it. "main" is included alongside any
+	// named `[tool.poetry.group.*]` tables so every dependency can be
+	// classified the same way regardless of where it was declared.
+	GroupDependencies map[string]map[string]struct{}
+	// Optional holds the normalized names of dependencies declared with
+	// `optional = true` under `[tool.poetry.dependencies]` or listed under
+	// any PEP 621 `[project.optional-dependencies]` extra.
+	Optional map[string]struct{}
+	// GitDependencies maps a normalized name to the git coordinates declared
+	// for it in pyproject.toml, when any.
+	GitDependencies map[string]gitDependency
+}
+
+// readPyProject reads and parses the pyproject.toml sitting next to a
+// poetry.lock. It returns an error if the file is missing or malformed so
+// that the caller can fall back to relationship-less reporting.
+func readPyProject(fsys fs.FS, path string) (*pyProjectInfo, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, xerrors.Errorf("file open error: %w", err)
+	}
+	defer f.Close()
+
+	return parsePyProject(f)
+}
+
+func parsePyProject(r io.Reader) (*pyProjectInfo, error) {
+	var raw rawPyProject
+	if _, err := toml.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, xerrors.Errorf("toml decode error: %w", err)
+	}
+
+	info := &pyProjectInfo{
+		MainDependencies:  map[string]struct{}{},
+		GroupDependencies: map[string]map[string]struct{}{},
+		Optional:          map[string]struct{}{},
+		GitDependencies:   map[string]gitDependency{},
+	}
+
+	mainDeps := make(map[string]struct{}, len(raw.Tool.Poetry.Dependencies)+len(raw.Project.Dependencies))
+
+	// Legacy `[tool.poetry.dependencies]`.
+	for name, spec := range raw.Tool.Poetry.Dependencies {
+		if strings.EqualFold(name, pythonPseudoDependency) {
+			continue
+		}
+		norm := normalizeName(name)
+		info.MainDependencies[norm] = struct{}{}
+		mainDeps[norm] = struct{}{}
+
+		if isOptionalDependencySpec(spec) {
+			info.Optional[norm] = struct{}{}
+		}
+		if dep, ok := parseGitDependencySpec(spec); ok {
+			info.GitDependencies[norm] = dep
+		}
+	}
+
+	// PEP 621 `[project].dependencies`, used by Poetry 2.0+ instead of
+	// `[tool.poetry.dependencies]`.
+	for _, req := range raw.Project.Dependencies {
+		norm, ok := pep508Name(req)
+		if !ok {
+			continue
+		}
+		info.MainDependencies[norm] = struct{}{}
+		mainDeps[norm] = struct{}{}
+	}
+
+	info.GroupDependencies[mainGroupName] = mainDeps
+
+	// PEP 621 `[project.optional-dependencies].<extra>`: each extra behaves
+	// like its own dependency group.
+	for extra, reqs := range raw.Project.OptionalDependencies {
+		deps := make(map[string]struct{}, len(reqs))
+		for _, req := range reqs {
+			norm, ok := pep508Name(req)
+			if !ok {
+				continue
+			}
+			deps[norm] = struct{}{}
+			info.Optional[norm] = struct{}{}
+		}
+		info.GroupDependencies[extra] = deps
+	}
+
+	// Legacy `[tool.poetry.group.<name>.dependencies]`.
+	for group, g := range raw.Tool.Poetry.Group {
+		deps := info.GroupDependencies[group]
+		if deps == nil {
+			deps = make(map[string]struct{}, len(g.Dependencies))
+		}
+		for name, spec := range g.Dependencies {
+			norm := normalizeName(name)
+			deps[norm] = struct{}{}
+			if dep, ok := parseGitDependencySpec(spec); ok {
+				info.GitDependencies[norm] = dep
+			}
+		}
+		info.GroupDependencies[group] = deps
+	}
+
+	// PEP 735 `[dependency-groups]`, resolving `{include-group = "..."}`
+	// references to other groups.
+	for group, items := range raw.DependencyGroups {
+		deps := info.GroupDependencies[group]
+		if deps == nil {
+			deps = make(map[string]struct{}, len(items))
+		}
+		resolveDependencyGroupItems(raw.DependencyGroups, group, items, deps, map[string]struct{}{})
+		info.GroupDependencies[group] = deps
+	}
+
+	return info, nil
+}
+
+// resolveDependencyGroupItems flattens a PEP 735 dependency-group entry into
+// normalized package names, following `{include-group = "..."}` references.
+// visiting guards against cycles between groups.
+func resolveDependencyGroupItems(all map[string][]any, group string, items []any, into map[string]struct{}, visiting map[string]struct{}) {
+	if _, ok := visiting[group]; ok {
+		return
+	}
+	visiting[group] = struct{}{}
+
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			if norm, ok := pep508Name(v); ok {
+				into[norm] = struct{}{}
+			}
+		case map[string]any:
+			if included, ok := v["include-group"].(string); ok {
+				resolveDependencyGroupItems(all, included, all[included], into, visiting)
+			}
+		}
+	}
+}
+
+// parseGitDependencySpec extracts git coordinates from a dependency value
+// such as `{git = "...", tag = "..."}`. Plain version-constraint strings
+// (e.g. "^1.2.3") are ignored.
+func parseGitDependencySpec(spec any) (gitDependency, bool) {
+	table, ok := spec.(map[string]any)
+	if !ok {
+		return gitDependency{}, false
+	}
+
+	url, _ := table["git"].(string)
+	if url == "" {
+		return gitDependency{}, false
+	}
+
+	dep := gitDependency{URL: url}
+	dep.Tag, _ = table["tag"].(string)
+	dep.Rev, _ = table["rev"].(string)
+	dep.Branch, _ = table["branch"].(string)
+	return dep, true
+}
+
+// isOptionalDependencySpec reports whether a dependency value carries
+// `optional = true`, e.g. `typing-inspect = {version = "0.9.0", optional = true}`.
+func isOptionalDependencySpec(spec any) bool {
+	table, ok := spec.(map[string]any)
+	if !ok {
+		return false
+	}
+	optional, _ := table["optional"].(bool)
+	return optional
+}
+
+// normalizeName normalizes a Python distribution name per PEP 503: runs of
+// `-`, `_` and `.` are collapsed to a single `-` and the name is lowercased.
+// This is required to match names across pyproject.toml, poetry.lock
+// dependency tables and the lock entries themselves, which don't always
+// agree on case or separator.
+func normalizeName(name string) string {
+	name = strings.ToLower(name)
+	var b strings.Builder
+	b.Grow(len(name))
+	sep := false
+	for _, r := range name {
+		switch r {
+		case '-', '_', '.':
+			sep = true
+		default:
+			if sep && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			sep = false
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
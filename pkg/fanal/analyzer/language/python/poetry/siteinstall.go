@@ -0,0 +1,153 @@
+package poetry
+
+import (
+	"bufio"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/aquasecurity/trivy/pkg/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// recordFile is the name pip/Poetry write inside a `*.dist-info` directory,
+// listing every file that belongs to the installed distribution.
+const recordFile = "RECORD"
+
+// distInfoPattern matches a `<name>-<version>.dist-info` directory name, as
+// defined by the wheel/installed-distribution spec.
+var distInfoPattern = regexp.MustCompile(`^(.+)-([^-]+)\.dist-info$`)
+
+// installedPackage is a distribution found on disk via its `*.dist-info`
+// metadata directory, independent of what poetry.lock says should be there.
+type installedPackage struct {
+	Name       string
+	Version    string
+	RecordPath string
+	OwnedFiles []string
+}
+
+// findInstalledPackages walks fsys for `*.dist-info/RECORD` files and
+// returns the distributions they describe. This lets the poetry analyzer
+// reconcile what Poetry resolved against what is actually installed in a
+// site-packages tree, e.g. in a container image built from the lock file.
+func findInstalledPackages(fsys fs.FS) ([]installedPackage, error) {
+	var installed []installedPackage
+
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || !distInfoPattern.MatchString(d.Name()) {
+			return nil
+		}
+
+		m := distInfoPattern.FindStringSubmatch(d.Name())
+		name, version := m[1], m[2]
+
+		recordPath := filepath.Join(path, recordFile)
+		owned, err := parseRecord(fsys, recordPath, filepath.Dir(path))
+		if err != nil {
+			log.Logger.Debugf("Unable to parse %q: %s", recordPath, err)
+			return nil
+		}
+
+		installed = append(installed, installedPackage{
+			Name:       name,
+			Version:    version,
+			RecordPath: recordPath,
+			OwnedFiles: owned,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return installed, nil
+}
+
+// parseRecord reads a dist-info RECORD file and returns the file paths it
+// lists, resolved relative to siteDir (the site-packages directory the
+// dist-info folder itself lives in).
+func parseRecord(fsys fs.FS, recordPath, siteDir string) ([]string, error) {
+	f, err := fsys.Open(recordPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var files []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// RECORD is CSV (path,sha256-hash,size), but we only need the path
+		// and never need to handle a quoted/escaped one in practice.
+		rel, _, ok := strings.Cut(line, ",")
+		if !ok || rel == "" {
+			continue
+		}
+		files = append(files, filepath.Join(siteDir, rel))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// reconcileInstalledPackages attaches the installed files owned by each
+// poetry.lock package, and returns additional types.Application entries for
+// any installed distribution that has no corresponding lock entry (e.g. it
+// was installed on top of the Poetry environment rather than resolved by
+// it). A dist-info is only attached to a lock package when its name *and*
+// version both match; a stale/unsynced site-packages tree installing a
+// different version than the lock file resolved is treated the same as no
+// installed dist-info at all, and the actually-installed version is
+// reported as its own entry below. Those are kept separate rather than
+// merged so that a later file-ownership-overlap pass can drop the
+// redundant dist-info detection in favor of the lock-resolved version.
+func reconcileInstalledPackages(apps []types.Application, installed []installedPackage) []types.Application {
+	if len(installed) == 0 {
+		return nil
+	}
+
+	consumed := make(map[int]bool, len(installed))
+	for _, app := range apps {
+		for i := range app.Packages {
+			pkg := &app.Packages[i]
+			name := normalizeName(pkg.Name)
+
+			for idx, inst := range installed {
+				if consumed[idx] || normalizeName(inst.Name) != name || inst.Version != pkg.Version {
+					continue
+				}
+				pkg.InstalledFiles = inst.OwnedFiles
+				consumed[idx] = true
+				break
+			}
+		}
+	}
+
+	var extra []types.Application
+	for idx, inst := range installed {
+		if consumed[idx] {
+			continue
+		}
+		extra = append(extra, types.Application{
+			Type:     types.PythonPkg,
+			FilePath: inst.RecordPath,
+			Packages: types.Packages{
+				{
+					ID:             packageID(inst.Name, inst.Version),
+					Name:           inst.Name,
+					Version:        inst.Version,
+					InstalledFiles: inst.OwnedFiles,
+				},
+			},
+		})
+	}
+
+	return extra
+}
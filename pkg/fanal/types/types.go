@@ -0,0 +1,99 @@
+package types
+
+// LangType identifies the language/package-manager ecosystem an Application
+// was detected in, e.g. Poetry's poetry.lock or a plain Python site-packages
+// install with no lock file backing it.
+type LangType string
+
+const (
+	Poetry    LangType = "poetry"
+	PythonPkg LangType = "python-pkg"
+)
+
+// Relationship describes how a package relates to what the user explicitly
+// declared: RelationshipDirect for a package the user asked for by name,
+// RelationshipIndirect for one pulled in only because something else
+// depends on it.
+type Relationship int
+
+const (
+	RelationshipUnknown Relationship = iota
+	RelationshipDirect
+	RelationshipIndirect
+)
+
+// RefType identifies what an ExternalRef points at.
+type RefType string
+
+const (
+	// RefVCS points at the upstream version-control repository a package
+	// was resolved from, e.g. a git-sourced Poetry dependency.
+	RefVCS RefType = "vcs"
+)
+
+// ExternalRef is a link from a package to a resource outside of the
+// artifact it was found in, such as its upstream source repository.
+type ExternalRef struct {
+	Type RefType
+	URL  string
+}
+
+// Package is a single package/library detected in a scanned artifact.
+type Package struct {
+	ID      string
+	Name    string
+	Version string
+
+	// Indirect and Relationship both describe whether the package was
+	// explicitly declared by the user or pulled in transitively;
+	// Relationship is the richer of the two and should be preferred.
+	Indirect     bool
+	Relationship Relationship
+
+	// Dev and Groups classify which dependency group(s) a package belongs
+	// to, e.g. Poetry's "main" vs. named `[tool.poetry.group.*]` groups.
+	// Dev is a convenience flag: true whenever Groups doesn't include the
+	// ecosystem's default/production group.
+	Dev    bool
+	Groups []string
+
+	// Optional is true when the package was declared with an
+	// ecosystem-specific optional flag, e.g. Poetry's `optional = true`.
+	Optional bool
+
+	// DependsOn lists the IDs of the packages this package directly depends
+	// on, as declared/resolved by its ecosystem's lock file.
+	DependsOn []string
+
+	// ExternalReferences links this package to resources outside of the
+	// artifact it was found in, e.g. its upstream VCS repository.
+	ExternalReferences []ExternalRef
+
+	// InstalledFiles lists the file paths this package owns on disk, when
+	// that can be determined by cross-referencing an installed copy of the
+	// package (e.g. a `*.dist-info/RECORD`) rather than only its lock entry.
+	InstalledFiles []string
+}
+
+// Packages is a sortable list of Package, ordered by name and then ID so
+// that scan results are stable and diff-friendly.
+type Packages []Package
+
+func (p Packages) Len() int { return len(p) }
+
+func (p Packages) Less(i, j int) bool {
+	if p[i].Name != p[j].Name {
+		return p[i].Name < p[j].Name
+	}
+	return p[i].ID < p[j].ID
+}
+
+func (p Packages) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+
+// Application is a language-ecosystem-specific collection of packages found
+// at a single file, e.g. a poetry.lock or a dist-info RECORD file.
+type Application struct {
+	Type     LangType
+	FilePath string
+	Packages Packages
+}